@@ -0,0 +1,87 @@
+package httplog
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// apacheTimeLayout is the Apache access log timestamp format, e.g.
+// "10/Oct/2000:13:55:36 -0700".
+const apacheTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// CommonFormatter renders a log line in the Apache Common Log Format, so
+// httplog output can feed existing tooling (GoAccess, AWStats, Splunk
+// field extractors) that already knows how to parse it.
+//
+//   127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+type CommonFormatter struct{}
+
+// Format implements the Formatter interface.
+func (f *CommonFormatter) Format(e *Entry) []byte {
+	return commonLogLine(e)
+}
+
+// CombinedFormatter renders a log line in the Apache Combined Log Format:
+// the Common Log Format plus the Referer and User-Agent request headers.
+//
+//   127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://referer" "user-agent"
+type CombinedFormatter struct{}
+
+// Format implements the Formatter interface.
+func (f *CombinedFormatter) Format(e *Entry) []byte {
+	referer := e.Referer
+	if referer == "" {
+		referer = "-"
+	}
+
+	buf := commonLogLine(e)
+	buf = append(buf, ` "`...)
+	buf = append(buf, referer...)
+	buf = append(buf, `" "`...)
+	buf = append(buf, e.UA...)
+	buf = append(buf, '"')
+	return buf
+}
+
+// remoteUser extracts the authenticated user for CommonFormatter and
+// CombinedFormatter, preferring userinfo on the request URL and falling
+// back to HTTP Basic Auth credentials.
+func remoteUser(r *http.Request) string {
+	if r.URL.User != nil {
+		return r.URL.User.Username()
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
+
+func commonLogLine(e *Entry) []byte {
+	remoteUser := e.RemoteUser
+	if remoteUser == "" {
+		remoteUser = "-"
+	}
+
+	size := "-"
+	if e.Size > 0 {
+		size = strconv.FormatInt(e.Size, 10)
+	}
+
+	buf := make([]byte, 0)
+	buf = append(buf, e.IP...)
+	buf = append(buf, " - "...)
+	buf = append(buf, remoteUser...)
+	buf = append(buf, " ["...)
+	buf = append(buf, e.Time.Format(apacheTimeLayout)...)
+	buf = append(buf, `] "`...)
+	buf = append(buf, e.Method...)
+	buf = append(buf, ' ')
+	buf = append(buf, e.RequestURI...)
+	buf = append(buf, ' ')
+	buf = append(buf, e.Proto...)
+	buf = append(buf, `" `...)
+	buf = append(buf, strconv.Itoa(e.Status)...)
+	buf = append(buf, ' ')
+	buf = append(buf, size...)
+	return buf
+}
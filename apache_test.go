@@ -0,0 +1,85 @@
+package httplog_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gkats/httplog"
+)
+
+func TestCommonFormatter(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.CommonFormatter{})
+	r := httptest.NewRequest("GET", "/apache_pb.gif", nil)
+	l.SetRequestInfo(r)
+	l.SetStatus(200)
+	l.SetSize(2326)
+	l.Log()
+
+	tests := []string{
+		"192.0.2.1 - - [",
+		`] "GET /apache_pb.gif HTTP/1.1" 200 2326`,
+	}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+	if strings.Contains(w.Stream, "\"") && strings.Count(w.Stream, "\"") > 2 {
+		t.Errorf("Expected Common Log Format to omit Referer/User-Agent, got %v", w.Stream)
+	}
+	if count := strings.Count(w.Stream, "\n"); count != 1 {
+		t.Errorf("Expected exactly one trailing newline, got %v in %v", count, w.Stream)
+	}
+}
+
+func TestCommonFormatterZeroSize(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.CommonFormatter{})
+	r := httptest.NewRequest("GET", "http://example.com/logs", nil)
+	l.SetRequestInfo(r)
+	l.SetStatus(204)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "204 -") {
+		t.Errorf("Expected zero size to render as '-', got %v", w.Stream)
+	}
+}
+
+func TestCombinedFormatter(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.CombinedFormatter{})
+	r := httptest.NewRequest("GET", "/apache_pb.gif", nil)
+	r.Header.Set("Referer", "http://referer")
+	r.Header.Set("User-Agent", "user-agent")
+	l.SetRequestInfo(r)
+	l.SetStatus(200)
+	l.SetSize(2326)
+	l.Log()
+
+	tests := []string{
+		`"GET /apache_pb.gif HTTP/1.1" 200 2326 "http://referer" "user-agent"`,
+	}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+	if count := strings.Count(w.Stream, "\n"); count != 1 {
+		t.Errorf("Expected exactly one trailing newline, got %v in %v", count, w.Stream)
+	}
+}
+
+func TestCommonFormatterRemoteUserFromBasicAuth(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.CommonFormatter{})
+	r := httptest.NewRequest("GET", "http://example.com/logs", nil)
+	r.SetBasicAuth("frank", "secret")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "192.0.2.1 - frank [") {
+		t.Errorf("Expected %v to contain remote user 'frank'", w.Stream)
+	}
+}
@@ -0,0 +1,140 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// defaultMaxBodyBytes is how many bytes of a request body are captured
+// for logging when no explicit limit has been set with SetMaxBodyBytes.
+const defaultMaxBodyBytes = 4 * 1024
+
+// defaultBodyContentTypes are the request Content-Types whose bodies are
+// captured for logging when no explicit allowlist has been set with
+// SetBodyContentTypes.
+var defaultBodyContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+}
+
+// redactedValue replaces the value of any redacted param.
+const redactedValue = "[REDACTED]"
+
+// captureParams builds the Logger's params field from the request's URL
+// query string and, if its Content-Type is in the configured allowlist,
+// its body.
+func (l *httpLogger) captureParams(r *http.Request) string {
+	values := make(map[string]interface{})
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			values[k] = v[0]
+		}
+	}
+
+	ct := contentType(r.Header.Get("Content-Type"))
+	if r.Body != nil && l.bodyContentTypeAllowed(ct) {
+		captured := l.captureBody(r)
+		if ct == "application/x-www-form-urlencoded" {
+			mergeFormBody(values, captured)
+		} else {
+			mergeJSONBody(values, captured)
+		}
+	}
+
+	l.redact(values)
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// captureBody reads up to the Logger's MaxBodyBytes from r.Body through
+// an io.TeeReader, then restores r.Body to a reader over the captured
+// bytes followed by whatever of the body is still unread, so the next
+// handler in the chain sees the complete, original body.
+func (l *httpLogger) captureBody(r *http.Request) []byte {
+	max := l.maxBodyBytes
+	if max <= 0 {
+		max = defaultMaxBodyBytes
+	}
+
+	buf := &bytes.Buffer{}
+	io.Copy(io.Discard, io.TeeReader(io.LimitReader(r.Body, max), buf))
+	captured := buf.Bytes()
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), r.Body), r.Body}
+
+	return captured
+}
+
+// mergeFormBody parses a captured "application/x-www-form-urlencoded"
+// body and merges its values. It parses the already-captured bytes
+// rather than calling r.ParseForm, which would itself read r.Body to
+// EOF and leave it empty for the next handler in the chain.
+func mergeFormBody(values map[string]interface{}, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return
+	}
+	for k, v := range form {
+		if len(v) > 0 {
+			values[k] = v[0]
+		}
+	}
+}
+
+// mergeJSONBody merges a captured JSON object body into values. Bodies
+// that aren't a JSON object are kept as a raw string under "body".
+func mergeJSONBody(values map[string]interface{}, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(body, &m); err == nil {
+		for k, v := range m {
+			values[k] = v
+		}
+		return
+	}
+	values["body"] = string(body)
+}
+
+// bodyContentTypeAllowed reports whether ct is in the Logger's configured
+// allowlist of Content-Types to capture request bodies for.
+func (l *httpLogger) bodyContentTypeAllowed(ct string) bool {
+	for _, a := range l.bodyContentTypes {
+		if a == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// redact blanks out the value of any key in the Logger's RedactFields, so
+// sensitive params never reach the log output.
+func (l *httpLogger) redact(values map[string]interface{}) {
+	for _, field := range l.redactFields {
+		if _, ok := values[field]; ok {
+			values[field] = redactedValue
+		}
+	}
+}
+
+// contentType strips any parameters (e.g. "; charset=utf-8") from a
+// Content-Type header value.
+func contentType(h string) string {
+	ct, _, err := mime.ParseMediaType(h)
+	if err != nil {
+		return h
+	}
+	return ct
+}
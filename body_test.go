@@ -0,0 +1,118 @@
+package httplog_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gkats/httplog"
+)
+
+func TestSetRequestInfoIgnoresUnlistedContentType(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("plain text body"))
+	r.Header.Set("Content-Type", "text/plain")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "params={}") {
+		t.Errorf("Expected %v to contain 'params={}'", w.Stream)
+	}
+}
+
+func TestSetRequestInfoPreservesBodyForDownstreamHandler(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	body := "{\"foo\":\"bar\"}"
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	l.SetRequestInfo(r)
+
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("Expected downstream body to be %v, got %v", body, string(got))
+	}
+}
+
+func TestSetRequestInfoPreservesFormBodyForDownstreamHandler(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	body := "foo=bar&baz=qux"
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	l.SetRequestInfo(r)
+
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("Expected downstream body to be %v, got %v", body, string(got))
+	}
+}
+
+func TestSetMaxBodyBytesCapsCapturedBody(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetMaxBodyBytes(5)
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("{\"foo\":\"bar\"}"))
+	r.Header.Set("Content-Type", "application/json")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "\"body\":") {
+		t.Errorf("Expected truncated body to fall back to a raw 'body' param, got %v", w.Stream)
+	}
+	if strings.Contains(w.Stream, "\"foo\":\"bar\"") {
+		t.Errorf("Expected %v to not contain the full, untruncated body", w.Stream)
+	}
+}
+
+func TestSetBodyContentTypesRestrictsCapture(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetBodyContentTypes([]string{"application/json"})
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("foo=bar"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "params={}") {
+		t.Errorf("Expected form body to be ignored, got %v", w.Stream)
+	}
+}
+
+func TestSetRequestInfoCapturesFormParams(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("foo=bar"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "params={\"foo\":\"bar\"}") {
+		t.Errorf("Expected %v to contain 'params={\"foo\":\"bar\"}'", w.Stream)
+	}
+}
+
+func TestSetRedactFieldsBlanksSensitiveParams(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetRedactFields([]string{"password"})
+	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("{\"user\":\"joe\",\"password\":\"hunter2\"}"))
+	r.Header.Set("Content-Type", "application/json")
+	l.SetRequestInfo(r)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "\"password\":\"[REDACTED]\"") {
+		t.Errorf("Expected %v to contain redacted password", w.Stream)
+	}
+	if strings.Contains(w.Stream, "hunter2") {
+		t.Errorf("Expected %v to not contain the raw password", w.Stream)
+	}
+}
@@ -0,0 +1,28 @@
+package httplog
+
+import (
+	"context"
+	"io"
+)
+
+// contextKey is an unexported type to avoid collisions with context keys
+// defined in other packages.
+type contextKey struct{ name string }
+
+var loggerContextKey = &contextKey{"httplog-logger"}
+
+// NewContext returns a copy of ctx carrying the supplied Logger. Downstream
+// handlers retrieve it with FromContext.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the Logger stored in ctx by NewContext. If ctx
+// carries no Logger, FromContext returns a Logger that discards its
+// output, so callers can use the result without a nil check.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey).(Logger); ok {
+		return l
+	}
+	return New(io.Discard)
+}
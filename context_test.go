@@ -0,0 +1,33 @@
+package httplog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gkats/httplog"
+)
+
+func TestNewContextAndFromContext(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	ctx := httplog.NewContext(context.Background(), l)
+	got := httplog.FromContext(ctx)
+	got.Add("uid", 1234)
+	got.Log()
+
+	if !strings.Contains(w.Stream, "uid=1234") {
+		t.Errorf("Expected %v to contain 'uid=1234'", w.Stream)
+	}
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	l := httplog.FromContext(context.Background())
+	if l == nil {
+		t.Fatal("Expected FromContext to return a non-nil Logger")
+	}
+	// Should be safe to use without panicking, even though nothing was
+	// ever stored in the context.
+	l.Log()
+}
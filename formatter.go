@@ -0,0 +1,172 @@
+package httplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a snapshot of all the fields collected for a single request. It
+// is built by the Logger and handed to a Formatter, which decides how the
+// fields are laid out in the final log line.
+type Entry struct {
+	Level      Level
+	Time       time.Time
+	IP         string
+	Method     string
+	Path       string
+	UA         string
+	Status     int
+	Params     string
+	Size       int64
+	DurationMS int64
+	Error      string
+	Extras     map[string]interface{}
+
+	// RequestURI, Proto, Referer and RemoteUser are only used by
+	// CommonFormatter and CombinedFormatter, which need them to render
+	// an Apache-compatible request line.
+	RequestURI string
+	Proto      string
+	Referer    string
+	RemoteUser string
+}
+
+// Formatter turns an Entry into the bytes that get written to the Logger's
+// io.Writer. Swapping the Formatter changes the on-the-wire representation
+// of a log line without touching how the Logger collects its fields.
+type Formatter interface {
+	Format(*Entry) []byte
+}
+
+// TextFormatter produces the default, human-readable log line: a single
+// line of space separated "key=value" pairs.
+//
+//   level=I time=2017-07-08T17:08:12UTC ip=193.92.20.19 method=GET path=/logs ua=curl/7.54.0 status=200 params={} size=1280 duration_ms=12
+type TextFormatter struct{}
+
+// Format implements the Formatter interface.
+func (f *TextFormatter) Format(e *Entry) []byte {
+	buf := make([]byte, 0)
+	buf = append(buf, "level="+e.Level.String()...)
+	buf = append(buf, " time="+e.Time.Format("2006-01-02T15:04:05MST")...)
+	buf = append(buf, " ip="+e.IP...)
+	buf = append(buf, " method="+e.Method...)
+	buf = append(buf, " path="+e.Path...)
+	buf = append(buf, " ua="+e.UA...)
+	buf = append(buf, " status="+strconv.Itoa(e.Status)...)
+	buf = append(buf, " params="+e.Params...)
+	buf = append(buf, " size="+strconv.FormatInt(e.Size, 10)...)
+	buf = append(buf, " duration_ms="+strconv.FormatInt(e.DurationMS, 10)...)
+	if e.Error != "" {
+		buf = append(buf, " error="+e.Error...)
+	}
+	for k, v := range e.Extras {
+		buf = append(buf, " "+k+"="+fmt.Sprintf("%v", v)...)
+	}
+	return buf
+}
+
+// JSONFormatter produces a single JSON object per request, suitable for
+// ingestion by log pipelines that expect structured output.
+//
+//   {"duration_ms":12,"ip":"193.92.20.19","level":"info","method":"GET","params":{},"path":"/logs","size":1280,"status":200,"time":"2017-07-08T17:08:12Z","ua":"curl/7.54.0"}
+type JSONFormatter struct{}
+
+// Format implements the Formatter interface.
+func (f *JSONFormatter) Format(e *Entry) []byte {
+	m := map[string]interface{}{
+		"level":       jsonLevel(e.Level),
+		"time":        e.Time.Format(time.RFC3339),
+		"ip":          e.IP,
+		"method":      e.Method,
+		"path":        e.Path,
+		"ua":          e.UA,
+		"status":      e.Status,
+		"size":        e.Size,
+		"duration_ms": e.DurationMS,
+	}
+	if len(e.Params) > 0 {
+		if json.Valid([]byte(e.Params)) {
+			m["params"] = json.RawMessage(e.Params)
+		} else {
+			m["params"] = e.Params
+		}
+	}
+	if e.Error != "" {
+		m["error"] = e.Error
+	}
+	for k, v := range e.Extras {
+		m[k] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return []byte("{}")
+	}
+	return b
+}
+
+func jsonLevel(lv Level) string {
+	switch lv {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// LogfmtFormatter produces a logfmt-style log line: space separated
+// "key=value" pairs, with values quoted whenever they contain a space, a
+// double quote or an "=".
+//
+//   level=I time=2017-07-08T17:08:12Z ip=193.92.20.19 method=GET path=/logs ua=curl/7.54.0 status=200 params={} size=1280 duration_ms=12
+type LogfmtFormatter struct{}
+
+// Format implements the Formatter interface.
+func (f *LogfmtFormatter) Format(e *Entry) []byte {
+	pairs := []struct {
+		key   string
+		value interface{}
+	}{
+		{"level", e.Level.String()},
+		{"time", e.Time.Format(time.RFC3339)},
+		{"ip", e.IP},
+		{"method", e.Method},
+		{"path", e.Path},
+		{"ua", e.UA},
+		{"status", e.Status},
+		{"params", e.Params},
+		{"size", e.Size},
+		{"duration_ms", e.DurationMS},
+	}
+	buf := make([]byte, 0)
+	for i, p := range pairs {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, p.key+"="+logfmtValue(p.value)...)
+	}
+	if e.Error != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, "error="+logfmtValue(e.Error)...)
+	}
+	for k, v := range e.Extras {
+		buf = append(buf, ' ')
+		buf = append(buf, k+"="+logfmtValue(v)...)
+	}
+	return buf
+}
+
+func logfmtValue(v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	if strings.ContainsAny(s, " \"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package httplog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gkats/httplog"
+)
+
+func TestNewWithFormatterJSON(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.JSONFormatter{})
+	l.SetStatus(200)
+	l.Add("uid", 1234)
+	l.Log()
+
+	tests := []string{
+		"\"level\":\"info\"",
+		"\"status\":200",
+		"\"uid\":1234",
+	}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+}
+
+func TestSetFormatterLogfmt(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetFormatter(&httplog.LogfmtFormatter{})
+	l.SetStatus(404)
+	l.Log()
+
+	tests := []string{
+		"level=I",
+		"status=404",
+	}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+}
+
+func TestLogfmtFormatterQuotesSpaces(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.NewWithFormatter(w, &httplog.LogfmtFormatter{})
+	l.Add("ref", "two words")
+	l.Log()
+
+	want := `ref="two words"`
+	if !strings.Contains(w.Stream, want) {
+		t.Errorf("Expected %v to contain '%v'", w.Stream, want)
+	}
+}
@@ -11,7 +11,7 @@
 // blank space while the parameter key and its value are separated by the "="
 // character. Here's an example log output for a single request.
 //
-//   level=I time=2017-07-08T17:08:12UTC ip=193.92.20.19 method=GET path=/logs ua=Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.81 Safari/537.36 status=200 params={}
+//   level=I time=2017-07-08T17:08:12UTC ip=193.92.20.19 method=GET path=/logs ua=Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.81 Safari/537.36 status=200 params={} size=1280 duration_ms=12
 //
 // Standalone usage
 //
@@ -61,18 +61,73 @@
 //   l.Log()
 //   // => level=I [...] uid=1234 meta=new-request
 //
+// Output formats
+//
+// By default a Logger renders its entries with the TextFormatter, the
+// human-readable "key=value" line shown above. Use NewWithFormatter, or
+// SetFormatter on an existing Logger, to switch to JSONFormatter or
+// LogfmtFormatter instead.
+//
+//   l := httplog.NewWithFormatter(os.Stdout, &httplog.JSONFormatter{})
+//   l.Log()
+//   // => {"duration_ms":0,"ip":"","level":"info","method":"","path":"","size":0,"status":0,"time":"...","ua":""}
+//
+// Request IDs
+//
+// WithLogging reads the incoming X-Request-ID header, or generates one if
+// it's missing, echoes it back on the response, and attaches it to a
+// Logger scoped to that request. That Logger is reachable from downstream
+// handlers through the request's context, so any extra parameters they
+// add only apply to the current request.
+//
+//   func (h *customHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//     httplog.FromContext(r.Context()).Add("user_id", u.ID)
+//     w.WriteHeader(200)
+//   }
+//
+// Levels
+//
+// Every log entry has a Level: Debug, Info, Warn or Error. WithLogging
+// picks the level from the response status automatically (2xx/3xx is
+// Info, 4xx is Warn, 5xx is Error), and logs a recovered panic at Error
+// with the stack trace attached. Use SetMinLevel to drop entries below a
+// given severity, e.g. to silence Debug output in production.
+//
+//   l := httplog.New(os.Stdout)
+//   l.SetMinLevel(httplog.LevelInfo)
+//
+// Request body capture
+//
+// SetRequestInfo also captures request params: the URL query string plus,
+// for requests whose Content-Type is in the configured allowlist, the
+// body. By default the allowlist is ["application/json",
+// "application/x-www-form-urlencoded"], and at most 4 KiB of the body is
+// captured, both configurable with SetBodyContentTypes and
+// SetMaxBodyBytes. Capturing the body never consumes it: the next
+// handler in the chain still sees the complete, original body. Use
+// SetRedactFields to blank out sensitive param values, e.g. passwords or
+// tokens, before they reach the log output.
+//
+//   l := httplog.New(os.Stdout)
+//   l.SetMaxBodyBytes(1024)
+//   l.SetRedactFields([]string{"password"})
+//
+// Apache-compatible output
+//
+// CommonFormatter and CombinedFormatter render the Apache Common and
+// Combined Log Formats, for tooling (GoAccess, AWStats, Splunk field
+// extractors) that already knows how to parse them.
+//
+//   l := httplog.NewWithFormatter(os.Stdout, &httplog.CombinedFormatter{})
+//   http.Handle("/logs", httplog.WithLogging(&customHandler{}, l))
+//   // => 127.0.0.1 - - [08/Jul/2017:17:08:12 +0000] "GET /logs HTTP/1.1" 200 1280 "-" "curl/7.54.0"
+//
 package httplog
 
 import (
-	"bufio"
-	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/http/httputil"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -92,33 +147,72 @@ type Logger interface {
 	SetStatus(int)
 	SetRequestInfo(*http.Request)
 	Add(string, interface{})
+	SetFormatter(Formatter)
+	SetSize(int64)
+	SetDuration(time.Duration)
+	SetLevel(Level)
+	SetMinLevel(Level)
+	SetError(error)
+	SetMaxBodyBytes(int64)
+	SetBodyContentTypes([]string)
+	SetRedactFields([]string)
+	Derive() Logger
 }
 
 // Concrete implementation of the Logger interface
 type httpLogger struct {
-	w      io.Writer
-	ip     string
-	method string
-	path   string
-	ua     string
-	params string
-	status int
-	reqRaw []byte
-	extras map[string]interface{}
-}
-
-// New returns a Logger configured with the supplied io.Writer.
+	w                io.Writer
+	ip               string
+	method           string
+	path             string
+	requestURI       string
+	proto            string
+	referer          string
+	remoteUser       string
+	ua               string
+	params           string
+	status           int
+	size             int64
+	duration         time.Duration
+	level            Level
+	minLevel         Level
+	err              error
+	maxBodyBytes     int64
+	bodyContentTypes []string
+	redactFields     []string
+	extras           map[string]interface{}
+	formatter        Formatter
+}
+
+// New returns a Logger configured with the supplied io.Writer. The log
+// lines are rendered with the default TextFormatter. Use NewWithFormatter
+// to pick a different output format.
 func New(w io.Writer) Logger {
+	return NewWithFormatter(w, &TextFormatter{})
+}
+
+// NewWithFormatter returns a Logger configured with the supplied io.Writer
+// and Formatter. The Formatter controls how the log entry is rendered,
+// e.g. as a human-readable line, JSON, or logfmt.
+func NewWithFormatter(w io.Writer, f Formatter) Logger {
 	return &httpLogger{
-		w:      w,
-		extras: make(map[string]interface{}, 0),
+		w:                w,
+		extras:           make(map[string]interface{}, 0),
+		formatter:        f,
+		level:            LevelInfo,
+		maxBodyBytes:     defaultMaxBodyBytes,
+		bodyContentTypes: defaultBodyContentTypes,
 	}
 }
 
 // Log produces the logging entry for a single request.
 // It appends a logging line to the io.Writer's stream, using the io.Writer's
 // Write function. The entry is terminated by the new line character.
+// Entries below the Logger's MinLevel, set with SetMinLevel, are dropped.
 func (l *httpLogger) Log() {
+	if l.level < l.minLevel {
+		return
+	}
 	l.w.Write(append(l.buildLogEntry(), '\n'))
 }
 
@@ -134,76 +228,138 @@ func (l *httpLogger) SetStatus(s int) {
 	l.status = s
 }
 
+// SetFormatter sets the Formatter used to render the log entry.
+func (l *httpLogger) SetFormatter(f Formatter) {
+	l.formatter = f
+}
+
+// SetSize sets the Logger's size field, the number of bytes written in the
+// response body, to the supplied value.
+func (l *httpLogger) SetSize(n int64) {
+	l.size = n
+}
+
+// SetDuration sets the Logger's duration field, how long the request took
+// to handle, to the supplied value.
+func (l *httpLogger) SetDuration(d time.Duration) {
+	l.duration = d
+}
+
+// SetLevel sets the severity of the log entry. The middleware sets this
+// automatically from the response status; standalone users can call it
+// directly.
+func (l *httpLogger) SetLevel(lv Level) {
+	l.level = lv
+}
+
+// SetMinLevel sets the minimum severity a log entry needs in order to be
+// written. Entries logged below this level are silently dropped, which is
+// useful for suppressing verbose Debug output in production.
+func (l *httpLogger) SetMinLevel(lv Level) {
+	l.minLevel = lv
+}
+
+// SetError attaches an error to the log entry. It is rendered as an
+// "error=" field, and is meant for incident triage: the middleware sets
+// it when the wrapped handler panics.
+func (l *httpLogger) SetError(err error) {
+	l.err = err
+}
+
+// SetMaxBodyBytes sets how many bytes of a request body SetRequestInfo
+// captures for logging. The default is 4 KiB.
+func (l *httpLogger) SetMaxBodyBytes(n int64) {
+	l.maxBodyBytes = n
+}
+
+// SetBodyContentTypes sets the allowlist of request Content-Types whose
+// bodies SetRequestInfo captures for logging. Bodies of any other
+// Content-Type are left untouched. The default is ["application/json",
+// "application/x-www-form-urlencoded"].
+func (l *httpLogger) SetBodyContentTypes(types []string) {
+	l.bodyContentTypes = types
+}
+
+// SetRedactFields sets which param keys, from the query string or a
+// captured request body, have their values blanked out before they reach
+// the log output, e.g. "password", "token" or "authorization".
+func (l *httpLogger) SetRedactFields(fields []string) {
+	l.redactFields = fields
+}
+
+// Derive returns a new Logger that shares the original's io.Writer,
+// Formatter, MinLevel and body capture and redaction settings, with its
+// own independent copy of the extra parameters added with Add. Request
+// fields, status, size, duration and error are not copied, and the level
+// starts at Info. Use Derive to get a per-request
+// Logger from a shared base Logger; calling Add on the derived Logger
+// does not affect the original, which makes it safe to use concurrently
+// across requests.
+func (l *httpLogger) Derive() Logger {
+	extras := make(map[string]interface{}, len(l.extras))
+	for k, v := range l.extras {
+		extras[k] = v
+	}
+	return &httpLogger{
+		w:                l.w,
+		formatter:        l.formatter,
+		extras:           extras,
+		level:            LevelInfo,
+		minLevel:         l.minLevel,
+		maxBodyBytes:     l.maxBodyBytes,
+		bodyContentTypes: l.bodyContentTypes,
+		redactFields:     l.redactFields,
+	}
+}
+
 // SetRequestInfo sets all Logger fields that can be extracted from the
 // supplied http.Request argument. These are:
 // - the request IP
 // - the request method
 // - the user agent header value
 // - the path
-// - the request parameters, either from the request body or from the query URL
+// - the request parameters, from the query string and, if the request's
+//   Content-Type is in the configured allowlist, the request body
+// - the request URI, protocol, Referer header and authenticated user,
+//   used only by CommonFormatter and CombinedFormatter
+//
+// Capturing the body never blocks the next handler in the chain from
+// reading it in full: see captureBody.
 func (l *httpLogger) SetRequestInfo(r *http.Request) {
 	l.ip = getIP(r)
-
-	// Get a request dump
-	l.reqRaw = reqDump(r)
-
-	var line string
-	pathRegexp, _ := regexp.Compile("(.+)\\s(.+)\\sHTTP")
-	userAgentRegexp, _ := regexp.Compile("User-Agent:\\s(.+)")
-	getParamsRegexp, _ := regexp.Compile("(.+)\\?(.+)")
-
-	// The raw request comes in lines, separated by \r\n
-	s := bufio.NewScanner(strings.NewReader(string(l.reqRaw)))
-	for s.Scan() {
-		line = s.Text()
-		l.setPath(line, pathRegexp, getParamsRegexp)
-		l.setUa(line, userAgentRegexp)
-	}
-	// Last line contains the request parameters
-	if len(l.params) == 0 {
-		l.params = line
-	}
+	l.method = r.Method
+	l.path = r.URL.Path
+	l.ua = r.UserAgent()
+	l.params = l.captureParams(r)
+	l.requestURI = r.RequestURI
+	l.proto = r.Proto
+	l.referer = r.Referer()
+	l.remoteUser = remoteUser(r)
 }
 
 func (l *httpLogger) buildLogEntry() []byte {
-	buf := make([]byte, 0)
-	buf = append(buf, "level=I"...)
-	buf = append(buf, " time="+time.Now().UTC().Format("2006-01-02T15:04:05MST")...)
-	buf = append(buf, " ip="+l.ip...)
-	buf = append(buf, " method="+l.method...)
-	buf = append(buf, " path="+l.path...)
-	buf = append(buf, " ua="+l.ua...)
-	buf = append(buf, " status="+strconv.Itoa(l.status)...)
-	buf = append(buf, " params="+l.params...)
-	for k, v := range l.extras {
-		buf = append(buf, " "+k+"="+fmt.Sprintf("%v", v)...)
-	}
-	return buf
-}
-
-func (l *httpLogger) setPath(path string, pathRegexp *regexp.Regexp, getParamsRegexp *regexp.Regexp) {
-	// Check for the request path portion
-	// example POST /path HTTP/1.1
-	matches := pathRegexp.FindStringSubmatch(path)
-	if len(matches) > 0 {
-		l.method = matches[1]
-		l.path = matches[2]
-		// Check for query string params (GET request)
-		// example GET /path?param1=value&param2=value
-		matches = getParamsRegexp.FindStringSubmatch(matches[2])
-		if len(matches) > 0 {
-			l.path = matches[1]
-			l.params = toJSON(matches[2])
-		}
-	}
-}
-
-func (l *httpLogger) setUa(h string, r *regexp.Regexp) {
-	// Check for user agent header
-	// example User-Agent: <ua>
-	if matches := r.FindStringSubmatch(h); len(matches) > 0 {
-		l.ua = matches[1]
+	var errMsg string
+	if l.err != nil {
+		errMsg = l.err.Error()
 	}
+	return l.formatter.Format(&Entry{
+		Level:      l.level,
+		Time:       time.Now().UTC(),
+		IP:         l.ip,
+		Method:     l.method,
+		Path:       l.path,
+		UA:         l.ua,
+		Status:     l.status,
+		Params:     l.params,
+		Size:       l.size,
+		DurationMS: l.duration.Milliseconds(),
+		Error:      errMsg,
+		Extras:     l.extras,
+		RequestURI: l.requestURI,
+		Proto:      l.proto,
+		Referer:    l.referer,
+		RemoteUser: l.remoteUser,
+	})
 }
 
 func getIP(r *http.Request) (ip string) {
@@ -217,17 +373,3 @@ func getIP(r *http.Request) (ip string) {
 	}
 	return
 }
-
-func reqDump(r *http.Request) (dump []byte) {
-	dump, err := httputil.DumpRequest(r, true)
-	if err != nil {
-		dump = []byte("")
-	}
-	return
-}
-
-// Poor man's JSON encoding
-func toJSON(s string) string {
-	r := strings.NewReplacer("=", "\": \"", "&", "\", \"")
-	return "{\"" + r.Replace(s) + "\"}"
-}
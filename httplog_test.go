@@ -43,6 +43,8 @@ func TestLog(t *testing.T) {
 		"ua=",
 		"status=",
 		"params=",
+		"size=",
+		"duration_ms=",
 		"\n",
 	}
 	count := 0
@@ -82,15 +84,16 @@ func TestSetRequestInfo(t *testing.T) {
 	l := httplog.New(w)
 	r := httptest.NewRequest("POST", "https://example.com/resources", strings.NewReader("{\"foo\": \"bar\"}"))
 	r.Header.Set("User-Agent", "request-ua")
+	r.Header.Set("Content-Type", "application/json")
 	l.SetRequestInfo(r)
 
 	l.Log()
 	tests := []string{
 		"ip=" + strings.Split(r.RemoteAddr, ":")[0],
 		"method=POST",
-		"path=https://example.com/resources",
+		"path=/resources",
 		"ua=request-ua",
-		"params={\"foo\": \"bar\"}",
+		"params={\"foo\":\"bar\"}",
 	}
 	for i, want := range tests {
 		if !strings.Contains(w.Stream, want) {
@@ -109,9 +112,9 @@ func TestSetRequestInfo(t *testing.T) {
 	tests = []string{
 		"ip=" + forwardedIP,
 		"method=GET",
-		"path=https://example.com/resources",
+		"path=/resources",
 		"ua=request-ua",
-		"params={\"foo\": \"bar\"}",
+		"params={\"foo\":\"bar\"}",
 	}
 	for i, want := range tests {
 		if !strings.Contains(w.Stream, want) {
@@ -120,6 +123,30 @@ func TestSetRequestInfo(t *testing.T) {
 	}
 }
 
+func TestDerive(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.Add("base", "value")
+
+	derived := l.Derive()
+	derived.Add("request_id", "abc")
+	derived.Log()
+
+	if !strings.Contains(w.Stream, "base=value") {
+		t.Errorf("Expected %v to contain 'base=value'", w.Stream)
+	}
+	if !strings.Contains(w.Stream, "request_id=abc") {
+		t.Errorf("Expected %v to contain 'request_id=abc'", w.Stream)
+	}
+
+	// Adding to the derived Logger must not leak into the original.
+	w.Flush()
+	l.Log()
+	if strings.Contains(w.Stream, "request_id=abc") {
+		t.Errorf("Expected %v to not contain 'request_id=abc'", w.Stream)
+	}
+}
+
 func TestLogExtras(t *testing.T) {
 	w := NewTestWriter()
 	l := httplog.New(w)
@@ -136,6 +163,8 @@ func TestLogExtras(t *testing.T) {
 		"ua=",
 		"status=",
 		"params=",
+		"size=",
+		"duration_ms=",
 		"uid=1234",
 		"secret=shhh!",
 		"\n",
@@ -0,0 +1,28 @@
+package httplog
+
+// Level represents the severity of a log entry, from the least to the
+// most severe: Debug, Info, Warn and Error.
+type Level int
+
+// The Logger's supported severity levels.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the short, single-letter code used in the default text
+// output: "D", "I", "W" or "E".
+func (lv Level) String() string {
+	switch lv {
+	case LevelDebug:
+		return "D"
+	case LevelWarn:
+		return "W"
+	case LevelError:
+		return "E"
+	default:
+		return "I"
+	}
+}
@@ -0,0 +1,49 @@
+package httplog_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gkats/httplog"
+)
+
+func TestSetLevel(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetLevel(httplog.LevelError)
+	l.Log()
+
+	if !strings.Contains(w.Stream, "level=E") {
+		t.Errorf("Expected %v to contain 'level=E'", w.Stream)
+	}
+}
+
+func TestSetMinLevelSuppressesLowerSeverity(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetMinLevel(httplog.LevelWarn)
+
+	l.SetLevel(httplog.LevelInfo)
+	l.Log()
+	if w.Stream != "" {
+		t.Errorf("Expected Info entry to be suppressed, got %v", w.Stream)
+	}
+
+	l.SetLevel(httplog.LevelError)
+	l.Log()
+	if !strings.Contains(w.Stream, "level=E") {
+		t.Errorf("Expected Error entry to be logged, got %v", w.Stream)
+	}
+}
+
+func TestSetError(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+	l.SetError(errors.New("boom"))
+	l.Log()
+
+	if !strings.Contains(w.Stream, "error=boom") {
+		t.Errorf("Expected %v to contain 'error=boom'", w.Stream)
+	}
+}
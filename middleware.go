@@ -1,7 +1,10 @@
 package httplog
 
 import (
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"time"
 )
 
 // WithLogging provides HTTP logging capabilities to an http.Handler.
@@ -37,19 +40,77 @@ type loggingHandler struct {
 }
 
 func (h loggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.logger.SetRequestInfo(r)
-	lrw := &loggingResponseWriter{ResponseWriter: w}
-	h.next.ServeHTTP(lrw, r)
-	h.logger.SetStatus(lrw.Status)
-	defer h.logger.Log()
+	id := requestIDFor(r)
+	w.Header().Set(requestIDHeader, id)
+
+	// Each request gets its own Logger, derived from the configured one,
+	// so that the extra parameters added while handling this request
+	// don't leak into (or race with) any other concurrent request.
+	reqLogger := h.logger.Derive()
+	reqLogger.Add("request_id", id)
+	reqLogger.SetRequestInfo(r)
+	r = r.WithContext(NewContext(r.Context(), reqLogger))
+
+	start := time.Now()
+	wrapped, rw := wrapResponseWriter(w)
+	rw.status = http.StatusOK
+	defer func() {
+		if rec := recover(); rec != nil {
+			if !rw.wroteHeader {
+				rw.WriteHeader(http.StatusInternalServerError)
+			} else {
+				rw.status = http.StatusInternalServerError
+			}
+			reqLogger.SetError(fmt.Errorf("panic: %v", rec))
+			reqLogger.Add("stack", string(debug.Stack()))
+		}
+		reqLogger.SetStatus(rw.status)
+		reqLogger.SetSize(rw.size)
+		reqLogger.SetDuration(time.Since(start))
+		reqLogger.SetLevel(levelForStatus(rw.status))
+		reqLogger.Log()
+	}()
+
+	h.next.ServeHTTP(wrapped, r)
 }
 
-type loggingResponseWriter struct {
+// levelForStatus picks the Level a response status should be logged at:
+// 2xx/3xx is Info, 4xx is Warn, 5xx is Error.
+func levelForStatus(status int) Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return LevelError
+	case status >= http.StatusBadRequest:
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
+}
+
+// rw wraps an http.ResponseWriter to record the response status and the
+// number of bytes written, defaulting the status to 200 the same way
+// net/http would if WriteHeader was never called explicitly.
+type rw struct {
 	http.ResponseWriter
-	Status int
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *rw) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
 }
 
-func (lrw *loggingResponseWriter) WriteHeader(status int) {
-	lrw.Status = status
-	lrw.ResponseWriter.WriteHeader(status)
+func (w *rw) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
 }
@@ -37,7 +37,9 @@ func TestWithLogging(t *testing.T) {
 		"path=",
 		"ua=",
 		"status=200",
-		"params={\"foo\": \"bar\"}",
+		"params={\"foo\":\"bar\"}",
+		"size=2",
+		"duration_ms=",
 		"\n",
 	}
 	count := 0
@@ -49,3 +51,184 @@ func TestWithLogging(t *testing.T) {
 		count = 0
 	}
 }
+
+type flushingHandler struct{}
+
+func (h *flushingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := w.(http.Flusher); !ok {
+		http.Error(w, "ResponseWriter does not implement http.Flusher", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, "ok")
+	w.(http.Flusher).Flush()
+}
+
+type readerFromHandler struct{}
+
+func (h *readerFromHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rf, ok := w.(io.ReaderFrom)
+	if !ok {
+		http.Error(w, "ResponseWriter does not implement io.ReaderFrom", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	rf.ReadFrom(strings.NewReader("0123456789012345"))
+}
+
+func TestWithLoggingCountsBytesWrittenViaReaderFrom(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	h := httplog.WithLogging(&readerFromHandler{}, l)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if !strings.Contains(w.Stream, "size=16") {
+		t.Errorf("Expected %v to contain 'size=16'", w.Stream)
+	}
+}
+
+type requestIDHandler struct{}
+
+func (h *requestIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	httplog.FromContext(r.Context()).Add("user_id", 42)
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestWithLoggingRequestID(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	h := httplog.WithLogging(&requestIDHandler{}, l)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Request-ID", "req-123")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := res.Header.Get("X-Request-ID"); got != "req-123" {
+		t.Errorf("Expected response X-Request-ID header to be 'req-123', got %v", got)
+	}
+	tests := []string{"request_id=req-123", "user_id=42"}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+}
+
+func TestWithLoggingGeneratesRequestID(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	h := httplog.WithLogging(&testHandler{}, l)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Header.Get("X-Request-ID") == "" {
+		t.Error("Expected response to carry a generated X-Request-ID header")
+	}
+}
+
+func TestWithLoggingExposesFlusher(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	h := httplog.WithLogging(&flushingHandler{}, l)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if !strings.Contains(w.Stream, "size=2") {
+		t.Errorf("Expected %v to contain 'size=2'", w.Stream)
+	}
+}
+
+type statusHandler struct{ status int }
+
+func (h *statusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(h.status)
+}
+
+func TestWithLoggingLevelFromStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantLevel string
+	}{
+		{http.StatusOK, "level=I"},
+		{http.StatusNotFound, "level=W"},
+		{http.StatusInternalServerError, "level=E"},
+	}
+	for i, tt := range tests {
+		w := NewTestWriter()
+		l := httplog.New(w)
+		h := httplog.WithLogging(&statusHandler{status: tt.status}, l)
+		ts := httptest.NewServer(h)
+
+		if _, err := http.Get(ts.URL); err != nil {
+			t.Fatal(err)
+		}
+		ts.Close()
+
+		if !strings.Contains(w.Stream, tt.wantLevel) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, tt.wantLevel)
+		}
+	}
+}
+
+type panickingHandler struct{}
+
+func (h *panickingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	panic("kaboom")
+}
+
+func TestWithLoggingRecoversFromPanic(t *testing.T) {
+	w := NewTestWriter()
+	l := httplog.New(w)
+
+	h := httplog.WithLogging(&panickingHandler{}, l)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status %v, got %v", http.StatusInternalServerError, res.StatusCode)
+	}
+
+	tests := []string{"level=E", "status=500", "error=panic: kaboom", "stack="}
+	for i, want := range tests {
+		if !strings.Contains(w.Stream, want) {
+			t.Errorf("(%v) Expected %v to contain '%v'", i, w.Stream, want)
+		}
+	}
+}
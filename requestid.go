@@ -0,0 +1,33 @@
+package httplog
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// requestIDHeader is the header used to read an incoming request ID and to
+// echo it back on the response.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDFor returns the request ID from the X-Request-ID header, or
+// generates a new one if the header is missing or empty.
+func requestIDFor(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); id != "" {
+		return id
+	}
+	return newRequestID()
+}
+
+// newRequestID generates a random version 4 UUID, used as a request ID
+// when the incoming request did not supply one.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}